@@ -11,9 +11,9 @@ package argp
 
 import (
 	"bufio"
-	"fmt"
 	"io"
 	"regexp"
+	"unicode/utf8"
 )
 
 /*
@@ -30,34 +30,15 @@ func Parse(
 	args []string, // tokenized output of entire io.Reader input.  Note args[0] is a "valid" argument - not a command name.
 	err error,
 ) {
-	if argLine == nil {
-		return nil, fmt.Errorf("nil io.Reader passed to parser")
+	segs, err := ParseSegments(argLine)
+	if err != nil {
+		return nil, err
 	}
-	escDbl, _ := regexp.Compile(`(\\\\)|(\\")`)
-	escAll, _ := regexp.Compile(`\\.`)
-
-	var argCurr string
-	s := cliConfig(argLine)
-	for s.Scan() {
-		// Note the scanner relies on the parser semantics below to improve its
-		// performance. If these semantics change, the scanner may also
-		// require coding changes.
-		switch tokenIDextract(s.Bytes()) {
-		case tArgument:
-			argCurr += escapeSubstitute(tokenExtract(s.Bytes()), escAll)
-		case tArgumentEncap:
-			argCurr += tokenExpose(tokenExtract(s.Bytes()), escDbl)
-		case tWhiteSpace:
-			if argCurr != "" {
-				args = append(args, argCurr)
-			}
-			argCurr = ""
+	for _, word := range segs {
+		var argCurr string
+		for _, seg := range word {
+			argCurr += seg.Text
 		}
-	}
-	if s.Err() != nil {
-		return nil, s.Err()
-	}
-	if argCurr != "" {
 		args = append(args, argCurr)
 	}
 	return
@@ -115,6 +96,27 @@ func scanConfig() func(data []byte, atEOF bool) (advance int, token []byte, err
 
 	tokenEncapComplete, _ := regexp.Compile(`(^"(([^\\"])|(\\.))*")|(^'[^']*')`)
 	tokenEncapPartial, _ := regexp.Compile(`(^"(([^\\"])|(\\.))*$)|(^'[^']*$)`)
+	danglingEscapeComplete, _ := regexp.Compile(`^\\$`)
+
+	// offset/line/col track the position of data[0] within the whole
+	// scanned input, updated as each recognized token is consumed, so that
+	// a ParseError built from the unconsumed remainder points at the byte
+	// that actually broke tokenization rather than data[0] of the buffer.
+	offset, line, col := 0, 1, 1
+	positionAdvance := func(consumed []byte) {
+		offset += len(consumed)
+		for _, b := range consumed {
+			if b == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+		}
+	}
+	parseErr := func(kind ParseErrorKind, offending []byte) *ParseError {
+		return &ParseError{Offset: offset, Line: line, Column: col, Bytes: offending, Kind: kind}
+	}
 
 	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
 		for {
@@ -124,6 +126,7 @@ func scanConfig() func(data []byte, atEOF bool) (advance int, token []byte, err
 				// situation where forcing a buffer read would only
 				// produce a single whitespace, it's therefore, unnecessary
 				// to force a buffer read.
+				positionAdvance(part)
 				return tokenGen(tWhiteSpace, part)
 			}
 			if part := tokenNotEncapComplete.Find(data); part != nil {
@@ -136,9 +139,11 @@ func scanConfig() func(data []byte, atEOF bool) (advance int, token []byte, err
 				// situation should not be a problem as this escape character
 				// will occupy the first byte of the buffer likely followed
 				// by any remaining text.
+				positionAdvance(part)
 				return tokenGen(tArgument, part)
 			}
 			if part := tokenEncapComplete.Find(data); part != nil {
+				positionAdvance(part)
 				return tokenGen(tArgumentEncap, part)
 			}
 			if !atEOF {
@@ -148,11 +153,32 @@ func scanConfig() func(data []byte, atEOF bool) (advance int, token []byte, err
 					// before reaching the end of the current buffer.
 					return 0, nil, nil
 				}
+				if danglingEscapeComplete.Find(data) != nil {
+					// a trailing lone backslash isn't necessarily dangling -
+					// the byte it would escape may simply not have been read
+					// into the buffer yet.
+					return 0, nil, nil
+				}
 			}
 			if atEOF && len(data) < 1 {
 				return 0, nil, nil
 			}
-			return len(data), data, fmt.Errorf("unable to tokenize: '%v'", data)
+			if atEOF {
+				if part := tokenEncapPartial.Find(data); part != nil {
+					kind := ErrUnterminatedDoubleQuote
+					if part[0] == '\'' {
+						kind = ErrUnterminatedSingleQuote
+					}
+					return len(data), data, parseErr(kind, part)
+				}
+				if danglingEscapeComplete.Find(data) != nil {
+					return len(data), data, parseErr(ErrDanglingEscape, data)
+				}
+			}
+			if !utf8.Valid(data) {
+				return len(data), data, parseErr(ErrInvalidUTF8, data)
+			}
+			return len(data), data, parseErr(ErrUnrecognizedInput, data)
 		}
 	}
 }
@@ -0,0 +1,47 @@
+package argp
+
+import "fmt"
+
+// ParseErrorKind categorizes why a ParseError occurred.
+type ParseErrorKind byte
+
+const (
+	ErrUnterminatedSingleQuote ParseErrorKind = iota
+	ErrUnterminatedDoubleQuote
+	ErrDanglingEscape
+	ErrInvalidUTF8
+	ErrUnrecognizedInput
+)
+
+func (k ParseErrorKind) String() string {
+	switch k {
+	case ErrUnterminatedSingleQuote:
+		return "unterminated single quote"
+	case ErrUnterminatedDoubleQuote:
+		return "unterminated double quote"
+	case ErrDanglingEscape:
+		return "dangling escape at end of input"
+	case ErrInvalidUTF8:
+		return "invalid UTF-8"
+	default:
+		return "unrecognized input"
+	}
+}
+
+/*
+ParseError reports a tokenization failure with enough positional detail for a
+caller - an interactive shell, say - to render a caret-under-token message
+pointing at the offending input, the way go/scanner reports a positioned
+literal error.
+*/
+type ParseError struct {
+	Offset int            // byte offset of Bytes[0] within the scanned input.
+	Line   int            // 1-based line number of Bytes[0].
+	Column int            // 1-based column (byte count since the preceding newline, or start of input) of Bytes[0].
+	Bytes  []byte         // the offending byte(s).
+	Kind   ParseErrorKind // the category of failure.
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v:%v: %v: %q", e.Line, e.Column, e.Kind, e.Bytes)
+}
@@ -0,0 +1,48 @@
+package argp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorUnterminatedDoubleQuote(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := Parse(strings.NewReader(`"abc`))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(ErrUnterminatedDoubleQuote, perr.Kind)
+	assrt.Equal(1, perr.Line)
+	assrt.Equal(1, perr.Column)
+}
+func TestParseErrorUnterminatedSingleQuote(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := Parse(strings.NewReader(`'abc`))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(ErrUnterminatedSingleQuote, perr.Kind)
+}
+func TestParseErrorDanglingEscape(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := Parse(strings.NewReader(`abc\`))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(ErrDanglingEscape, perr.Kind)
+	assrt.Equal(3, perr.Offset)
+	assrt.Equal(1, perr.Line)
+	assrt.Equal(4, perr.Column)
+}
+func TestParseErrorPositionAfterNewline(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := Parse(strings.NewReader("ok\n\"abc"))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(2, perr.Line)
+	assrt.Equal(1, perr.Column)
+}
@@ -0,0 +1,228 @@
+package argp
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Expand performs variable and command substitution over args, the per-argument
+Segments produced by ParseSegments.  It takes Segments rather than a plain
+[]string because the substitution rules depend on quoting: expansion happens
+inside unquoted and double-quoted Segments, but never inside SingleQuoted
+ones, and only unquoted expansions are subject to field re-splitting on IFS
+whitespace (space, tab, newline) - a double-quoted expansion result stays a
+single field no matter what it contains.
+
+env resolves a bare variable name (without the leading $) to its value and
+whether it's set; a nil env treats every variable as unset.  cmd runs the text
+found inside $(...) and returns its output; a nil cmd makes any $(...)
+encountered an error.
+
+Supported forms: $VAR, ${VAR}, ${VAR:-default}, ${VAR:+alt}, ${VAR:?msg}, and
+$(command).
+*/
+func Expand(
+	args [][]Segment,
+	env func(name string) (value string, ok bool),
+	cmd func(command string) (output string, err error),
+) (expanded []string, err error) {
+	for _, word := range args {
+		fields, werr := expandWord(word, env, cmd)
+		if werr != nil {
+			return nil, werr
+		}
+		expanded = append(expanded, fields...)
+	}
+	return
+}
+
+func expandWord(
+	segs []Segment,
+	env func(string) (string, bool),
+	cmd func(string) (string, error),
+) (fields []string, err error) {
+	var argCurr string
+	haveArg := false
+	for _, seg := range segs {
+		if seg.Quoted == SingleQuoted {
+			argCurr += seg.Text
+			haveArg = true
+			continue
+		}
+		raw := seg.Text
+		if seg.Quoted == DoubleQuoted {
+			raw = seg.Raw
+		}
+		text, eerr := expandText(raw, seg.Quoted == DoubleQuoted, env, cmd)
+		if eerr != nil {
+			return nil, eerr
+		}
+		if seg.Quoted == DoubleQuoted {
+			argCurr += text
+			haveArg = true
+			continue
+		}
+		// Unquoted: the expansion result is subject to field re-splitting -
+		// every IFS-delimited piece but the first starts a new field, the
+		// first piece is appended to whatever has already accumulated.
+		parts := strings.FieldsFunc(text, isIFSWhiteSpace)
+		if len(parts) == 0 {
+			continue
+		}
+		argCurr += parts[0]
+		haveArg = true
+		for _, part := range parts[1:] {
+			fields = append(fields, argCurr)
+			argCurr = part
+		}
+	}
+	if haveArg {
+		fields = append(fields, argCurr)
+	}
+	return fields, nil
+}
+
+func isIFSWhiteSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+func expandText(
+	s string,
+	quoted bool, // true for a DoubleQuoted segment's Raw, honoring shell backslash-escaping before expansion.
+	env func(string) (string, bool),
+	cmd func(string) (string, error),
+) (string, error) {
+	var out string
+	i, n := 0, len(s)
+	for i < n {
+		if quoted && s[i] == '\\' && i+1 < n && isDblQuoteEscapable(s[i+1]) {
+			// a backslash escaping one of \, `, $, ", or newline suppresses
+			// any expansion the escaped character would otherwise trigger -
+			// the escape itself is consumed, and a line continuation leaves
+			// no trace in the output.
+			if s[i+1] == '\n' {
+				i += 2
+				continue
+			}
+			out += string(s[i+1])
+			i += 2
+			continue
+		}
+		if s[i] != '$' || i+1 >= n {
+			out += string(s[i])
+			i++
+			continue
+		}
+		switch {
+		case s[i+1] == '(':
+			end, eerr := matchParen(s, i+2)
+			if eerr != nil {
+				return "", eerr
+			}
+			if cmd == nil {
+				return "", fmt.Errorf("command substitution %q requires a non-nil cmd function", s[i:end+1])
+			}
+			result, cerr := cmd(s[i+2 : end])
+			if cerr != nil {
+				return "", cerr
+			}
+			out += result
+			i = end + 1
+		case s[i+1] == '{':
+			braceEnd := strings.IndexByte(s[i+2:], '}')
+			if braceEnd < 0 {
+				return "", fmt.Errorf("unterminated parameter expansion: %q", s[i:])
+			}
+			braceEnd += i + 2
+			val, perr := expandBraceParam(s[i+2:braceEnd], env)
+			if perr != nil {
+				return "", perr
+			}
+			out += val
+			i = braceEnd + 1
+		case isNameStart(s[i+1]):
+			j := i + 1
+			for j < n && isNameChar(s[j]) {
+				j++
+			}
+			if env != nil {
+				if val, ok := env(s[i+1 : j]); ok {
+					out += val
+				}
+			}
+			i = j
+		default:
+			out += string(s[i])
+			i++
+		}
+	}
+	return out, nil
+}
+
+func matchParen(s string, start int) (end int, err error) {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated command substitution: %q", s[start:])
+}
+
+func expandBraceParam(body string, env func(string) (string, bool)) (string, error) {
+	name, op, word := body, "", ""
+	opIdx := -1
+	for _, candidate := range []string{":-", ":+", ":?"} {
+		if idx := strings.Index(body, candidate); idx >= 0 && (opIdx < 0 || idx < opIdx) {
+			opIdx, op = idx, candidate
+		}
+	}
+	if opIdx >= 0 {
+		name, word = body[:opIdx], body[opIdx+len(op):]
+	}
+	var val string
+	var ok bool
+	if env != nil {
+		val, ok = env(name)
+	}
+	set := ok && val != ""
+	switch op {
+	case ":-":
+		if set {
+			return val, nil
+		}
+		return word, nil
+	case ":+":
+		if set {
+			return word, nil
+		}
+		return "", nil
+	case ":?":
+		if set {
+			return val, nil
+		}
+		if word == "" {
+			word = "parameter not set"
+		}
+		return "", fmt.Errorf("%s: %s", name, word)
+	default:
+		if ok {
+			return val, nil
+		}
+		return "", nil
+	}
+}
+
+func isNameStart(b byte) bool {
+	return b == '_' || (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z')
+}
+func isNameChar(b byte) bool {
+	return isNameStart(b) || (b >= '0' && b <= '9')
+}
@@ -0,0 +1,117 @@
+package argp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEnv(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		val, ok := vars[name]
+		return val, ok
+	}
+}
+func testCmd(out string) func(string) (string, error) {
+	return func(string) (string, error) {
+		return out, nil
+	}
+}
+
+func TestParseSegmentsQuotingPreserved(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"abc"def'ghi'`))
+	assrt.Nil(err)
+	assrt.Equal(1, len(segs))
+	assrt.Equal(3, len(segs[0]))
+	assrt.Equal(DoubleQuoted, segs[0][0].Quoted)
+	assrt.Equal(Unquoted, segs[0][1].Quoted)
+	assrt.Equal(SingleQuoted, segs[0][2].Quoted)
+}
+func TestExpandSimpleVar(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`$HOME/bin`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"HOME": "/root"}), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"/root/bin"}, out)
+}
+func TestExpandSingleQuotedNotExpanded(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`'$HOME'`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"HOME": "/root"}), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"$HOME"}, out)
+}
+func TestExpandDoubleQuotedNoResplit(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"$LIST"`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"LIST": "a b c"}), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"a b c"}, out)
+}
+func TestExpandUnquotedResplits(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`$LIST`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"LIST": "a b c"}), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"a", "b", "c"}, out)
+}
+func TestExpandBraceDefault(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"${MISSING:-fallback}"`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(nil), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"fallback"}, out)
+}
+func TestExpandBraceAltAndRequired(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"${SET:+yes}"`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"SET": "1"}), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"yes"}, out)
+
+	segs, err = ParseSegments(strings.NewReader(`"${MISSING:?must be set}"`))
+	assrt.Nil(err)
+	_, err = Expand(segs, testEnv(nil), nil)
+	assrt.NotNil(err)
+	assrt.Equal(fmt.Sprintf("%v", err), "MISSING: must be set")
+}
+func TestExpandBraceOperatorInWordIsNotMistakenForOperator(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"${FOO:+bar:-baz}"`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"FOO": "1"}), nil)
+	assrt.Nil(err)
+	assrt.Equal([]string{"bar:-baz"}, out)
+}
+func TestExpandDoubleQuotedEscapeSuppressesExpansion(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"\$FOO \` + "`" + `cmd\` + "`" + ` \"lit\" \\x"`))
+	assrt.Nil(err)
+	out, err := Expand(segs, testEnv(map[string]string{"FOO": "bar"}), testCmd("shouldnotrun"))
+	assrt.Nil(err)
+	assrt.Equal([]string{`$FOO ` + "`" + `cmd` + "`" + ` "lit" \x`}, out)
+}
+func TestExpandCommandSubstitution(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`"rev=$(git rev-parse HEAD)"`))
+	assrt.Nil(err)
+	out, err := Expand(segs, nil, testCmd("abc123"))
+	assrt.Nil(err)
+	assrt.Equal([]string{"rev=abc123"}, out)
+}
+func TestExpandCommandSubstitutionNoCmdFunc(t *testing.T) {
+	assrt := assert.New(t)
+	segs, err := ParseSegments(strings.NewReader(`$(pwd)`))
+	assrt.Nil(err)
+	_, err = Expand(segs, nil, nil)
+	assrt.NotNil(err)
+}
@@ -0,0 +1,75 @@
+package argp
+
+import (
+	"flag"
+	"io"
+	"strings"
+)
+
+/*
+ParseFlags tokenizes r with Parse and feeds the resulting tokens to
+fs.Parse, bridging argp's tokenizer to the standard flag package that the
+package doc points to as the natural "next step" after tokenization.
+
+flag.FlagSet already understands GNU-style long options (--name=value,
+--name value) natively - a single leading dash and a double leading dash are
+treated identically by flag.Parse.  What it has no notion of is clustered
+short options, where "-abc" means "-a -b -c" (or "-a bc" if -a takes a
+value).  ParseFlags expands clusters of that form before handing the tokens
+to fs, using fs.Lookup to tell a boolean flag, which consumes no value, from
+one that does.
+*/
+func ParseFlags(fs *flag.FlagSet, r io.Reader) error {
+	args, err := Parse(r)
+	if err != nil {
+		return err
+	}
+	return fs.Parse(expandClusters(fs, args))
+}
+
+func expandClusters(fs *flag.FlagSet, args []string) (out []string) {
+	for _, arg := range args {
+		if !isClusteredShort(fs, arg) {
+			out = append(out, arg)
+			continue
+		}
+		letters := arg[1:]
+		for j := 0; j < len(letters); j++ {
+			name := letters[j : j+1]
+			f := fs.Lookup(name)
+			if f == nil || isBoolFlag(f.Value) {
+				out = append(out, "-"+name)
+				continue
+			}
+			// name takes a value: whatever remains of the cluster is that
+			// value ("-oVALUE"); with nothing left, flag.Parse will instead
+			// consume the following argument ("-o VALUE").
+			if j+1 < len(letters) {
+				out = append(out, "-"+name, letters[j+1:])
+			} else {
+				out = append(out, "-"+name)
+			}
+			break
+		}
+	}
+	return
+}
+
+// isClusteredShort reports whether arg looks like multiple single-letter
+// short options packed behind one dash, as opposed to a single long option
+// (with or without an attached "=value") that merely happens to be longer
+// than two characters.
+func isClusteredShort(fs *flag.FlagSet, arg string) bool {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' {
+		return false
+	}
+	if strings.ContainsRune(arg, '=') {
+		return false
+	}
+	return fs.Lookup(arg[1:]) == nil
+}
+
+func isBoolFlag(v flag.Value) bool {
+	bf, ok := v.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
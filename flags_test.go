@@ -0,0 +1,58 @@
+package argp
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFlagSet() (fs *flag.FlagSet, a, b, c *bool, out *string) {
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	a = fs.Bool("a", false, "")
+	b = fs.Bool("b", false, "")
+	c = fs.Bool("c", false, "")
+	out = fs.String("o", "", "")
+	return
+}
+
+func TestParseFlagsClusteredShortBooleans(t *testing.T) {
+	assrt := assert.New(t)
+	fs, a, b, c, _ := newTestFlagSet()
+	err := ParseFlags(fs, strings.NewReader(`-abc`))
+	assrt.Nil(err)
+	assrt.True(*a)
+	assrt.True(*b)
+	assrt.True(*c)
+}
+func TestParseFlagsClusteredShortWithAttachedValue(t *testing.T) {
+	assrt := assert.New(t)
+	fs, a, _, _, out := newTestFlagSet()
+	err := ParseFlags(fs, strings.NewReader(`-aofile.txt`))
+	assrt.Nil(err)
+	assrt.True(*a)
+	assrt.Equal(`file.txt`, *out)
+}
+func TestParseFlagsGNULongOptionEquals(t *testing.T) {
+	assrt := assert.New(t)
+	fs, _, _, _, out := newTestFlagSet()
+	err := ParseFlags(fs, strings.NewReader(`--o=file.txt`))
+	assrt.Nil(err)
+	assrt.Equal(`file.txt`, *out)
+}
+func TestParseFlagsGNULongOptionSpace(t *testing.T) {
+	assrt := assert.New(t)
+	fs, _, _, _, out := newTestFlagSet()
+	err := ParseFlags(fs, strings.NewReader(`--o file.txt`))
+	assrt.Nil(err)
+	assrt.Equal(`file.txt`, *out)
+}
+func TestParseFlagsRemainingArgs(t *testing.T) {
+	assrt := assert.New(t)
+	fs, a, _, _, _ := newTestFlagSet()
+	err := ParseFlags(fs, strings.NewReader(`-a pos1 pos2`))
+	assrt.Nil(err)
+	assrt.True(*a)
+	assrt.Equal([]string{"pos1", "pos2"}, fs.Args())
+}
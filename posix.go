@@ -0,0 +1,196 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+ParsePOSIX converts input into tokens following the word-splitting rules of
+the POSIX shell grammar (http://pubs.opengroup.org/onlinepubs/9699919799/utilities/V3_chap02.html#tag_18_02),
+a stricter variant of the semantics implemented by Parse.  It exists because
+Parse's quoting rules are a hybrid convenient for simple option lines but
+don't match a real shell closely enough to round-trip build-tooling style
+input.
+
+The differences from Parse:
+
+Inside single quotes no character is special - identical to Parse.
+
+Inside double quotes only the characters \, `, $, ", and newline may be
+escaped by a preceding backslash; a backslash before any other character is
+preserved literally in the output.  Parse also keeps such a backslash, but it
+removes a backslash inside double quotes only before \ or ", so it keeps the
+backslash before ` and $ as well.
+
+Outside of quotes a backslash immediately followed by a newline is a line
+continuation - both characters are removed and the token is unaffected - and
+an unquoted '#' occurring at the start of a token begins a comment that
+consumes the remainder of the line.
+
+As with Parse, the entire io.Reader is considered a single logical input;
+embedded newlines are significant only for line continuation and comment
+termination, not as token separators - tab, space, and newline are all IFS
+whitespace.
+
+An unterminated quote or a dangling escape at end of input is reported as a
+*ParseError, carrying the Line/Column/Offset of the character that started
+the unterminated construct, so a caller driving an interactive shell can
+render a caret-under-token message the same way it would for Parse.
+*/
+func ParsePOSIX(
+	argLine io.Reader, // all input provided by io.Reader is considered a single line.
+) (
+	args []string, // tokenized output of entire io.Reader input.
+	err error,
+) {
+	if argLine == nil {
+		return nil, fmt.Errorf("nil io.Reader passed to parser")
+	}
+	data, err := io.ReadAll(argLine)
+	if err != nil {
+		return nil, err
+	}
+	return parsePOSIX(data)
+}
+
+func parsePOSIX(data []byte) (args []string, err error) {
+	var argCurr strings.Builder
+	inArg := false
+	i, n := 0, len(data)
+	for i < n {
+		c := data[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, argCurr.String())
+				argCurr.Reset()
+				inArg = false
+			}
+			i++
+		case c == '#' && !inArg:
+			for i < n && data[i] != '\n' {
+				i++
+			}
+		case c == '\'':
+			inArg = true
+			qstart := i
+			i++
+			start := i
+			for i < n && data[i] != '\'' {
+				i++
+			}
+			if i >= n {
+				return nil, posixParseErr(data, qstart, ErrUnterminatedSingleQuote)
+			}
+			argCurr.Write(data[start:i])
+			i++
+		case c == '"':
+			inArg = true
+			qstart := i
+			i++
+			for i < n && data[i] != '"' {
+				if data[i] == '\\' && i+1 < n && isDblQuoteEscapable(data[i+1]) {
+					if data[i+1] == '\n' {
+						i += 2
+						continue
+					}
+					argCurr.WriteByte(data[i+1])
+					i += 2
+					continue
+				}
+				argCurr.WriteByte(data[i])
+				i++
+			}
+			if i >= n {
+				return nil, posixParseErr(data, qstart, ErrUnterminatedDoubleQuote)
+			}
+			i++
+		case c == '\\':
+			if i+1 >= n {
+				return nil, posixParseErr(data, i, ErrDanglingEscape)
+			}
+			if data[i+1] == '\n' {
+				// a line continuation has no token effect of its own - it
+				// must not flip inArg, or a continuation at the very start
+				// of input (or right after whitespace) produces a spurious
+				// empty-string argument.
+				i += 2
+				continue
+			}
+			inArg = true
+			argCurr.WriteByte(data[i+1])
+			i += 2
+		default:
+			inArg = true
+			argCurr.WriteByte(c)
+			i++
+		}
+	}
+	if inArg {
+		args = append(args, argCurr.String())
+	}
+	return
+}
+
+// posixParseErr builds the *ParseError for a tokenization failure at offset
+// within data, computing Line/Column by scanning everything up to offset -
+// parsePOSIX operates on the whole buffer at once, unlike scanConfig's
+// incremental scanning, so there's no running position to carry forward.
+func posixParseErr(data []byte, offset int, kind ParseErrorKind) *ParseError {
+	line, col := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &ParseError{Offset: offset, Line: line, Column: col, Bytes: data[offset:], Kind: kind}
+}
+
+func isDblQuoteEscapable(c byte) bool {
+	switch c {
+	case '\\', '`', '$', '"', '\n':
+		return true
+	}
+	return false
+}
+
+/*
+Quote returns s wrapped in single quotes so that it round-trips unchanged
+through ParsePOSIX.  Since single quotes admit no escaping of their own, an
+embedded single quote is closed, reproduced inside a backslash escape, and
+reopened: a literal ' becomes '\''.
+*/
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	var b strings.Builder
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			b.WriteString(`'\''`)
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+/*
+Join combines args into a single line suitable as input to ParsePOSIX, each
+argument individually quoted via Quote and separated by a single space, such
+that ParsePOSIX(strings.NewReader(Join(args))) reproduces args.
+*/
+func Join(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = Quote(a)
+	}
+	return strings.Join(quoted, " ")
+}
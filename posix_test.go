@@ -0,0 +1,154 @@
+package argp
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPOSIXSingleQuoteNoEscape(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader(`'a\"b\\c'`))
+	assrt.Nil(err)
+	assrt.Equal(len(args), 1)
+	assrt.Equal(`a\"b\\c`, args[0])
+}
+func TestPOSIXDoubleQuotePreservesUnknownEscape(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader(`"a\lb"`))
+	assrt.Nil(err)
+	assrt.Equal(len(args), 1)
+	assrt.Equal(`a\lb`, args[0])
+}
+func TestPOSIXDoubleQuoteHonoredEscapes(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader(`"a\\b\$c\"d"`))
+	assrt.Nil(err)
+	assrt.Equal(len(args), 1)
+	assrt.Equal(`a\b$c"d`, args[0])
+}
+func TestPOSIXLineContinuationOutsideQuotes(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader("ab\\\ncd"))
+	assrt.Nil(err)
+	assrt.Equal(len(args), 1)
+	assrt.Equal(`abcd`, args[0])
+}
+func TestPOSIXLineContinuationProducesNoSpuriousArg(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader("\\\n"))
+	assrt.Nil(err)
+	assrt.Equal(0, len(args))
+	args, err = ParsePOSIX(strings.NewReader("a \\\n b"))
+	assrt.Nil(err)
+	assrt.Equal([]string{"a", "b"}, args)
+}
+func TestPOSIXCommentAtStartOfToken(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader("abc #comment\ndef"))
+	assrt.Nil(err)
+	assrt.Equal(len(args), 2)
+	assrt.Equal(`abc`, args[0])
+	assrt.Equal(`def`, args[1])
+}
+func TestPOSIXHashMidTokenIsLiteral(t *testing.T) {
+	assrt := assert.New(t)
+	args, err := ParsePOSIX(strings.NewReader(`ab#cd`))
+	assrt.Nil(err)
+	assrt.Equal(len(args), 1)
+	assrt.Equal(`ab#cd`, args[0])
+}
+func TestPOSIXUnterminatedQuoteFails(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := ParsePOSIX(strings.NewReader(`"abc`))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(ErrUnterminatedDoubleQuote, perr.Kind)
+	assrt.Equal(0, perr.Offset)
+
+	_, err = ParsePOSIX(strings.NewReader(`'abc`))
+	assrt.NotNil(err)
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(ErrUnterminatedSingleQuote, perr.Kind)
+	assrt.Equal(0, perr.Offset)
+}
+func TestPOSIXDanglingEscapeFails(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := ParsePOSIX(strings.NewReader(`abc\`))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(ErrDanglingEscape, perr.Kind)
+	assrt.Equal(3, perr.Offset)
+	assrt.Equal(1, perr.Line)
+	assrt.Equal(4, perr.Column)
+}
+func TestPOSIXParseErrorPositionAfterNewline(t *testing.T) {
+	assrt := assert.New(t)
+	_, err := ParsePOSIX(strings.NewReader("ok\n\"abc"))
+	assrt.NotNil(err)
+	var perr *ParseError
+	assrt.True(errors.As(err, &perr))
+	assrt.Equal(2, perr.Line)
+	assrt.Equal(1, perr.Column)
+}
+func TestQuoteJoinRoundTrip(t *testing.T) {
+	assrt := assert.New(t)
+	in := []string{`abc`, `a b`, `it's`, ``, `"quoted"`}
+	args, err := ParsePOSIX(strings.NewReader(Join(in)))
+	assrt.Nil(err)
+	assrt.Equal(in, args)
+}
+
+// FuzzParsePOSIXDirect feeds arbitrary text straight to ParsePOSIX instead of
+// routing it through Join first.  Join always single-quotes its output, and
+// single quotes disable the new POSIX code paths - double-quote backslash
+// escaping, line continuation, and '#' comments - so a fuzz target built only
+// on Join/Quote round-tripping can never exercise, and could never have caught
+// a bug in, any of them.  This target's property is weaker (idempotence rather
+// than a golden oracle): the seeds steer the fuzzer into those paths, but the
+// property holds for a wrong result too, so the specific regressions are
+// pinned down by the Test functions above.
+func FuzzParsePOSIXDirect(f *testing.F) {
+	f.Add("abc def")
+	f.Add("\\\n")
+	f.Add("a \\\n b")
+	f.Add("abc #comment\ndef")
+	f.Add(`"a\"b\\c\$d"`)
+	f.Add(`"unterminated`)
+	f.Add(`trailing\`)
+	f.Fuzz(func(t *testing.T, in string) {
+		args, err := ParsePOSIX(strings.NewReader(in))
+		if err != nil {
+			return
+		}
+		out, err := ParsePOSIX(strings.NewReader(Join(args)))
+		if err != nil {
+			t.Fatalf("ParsePOSIX(%q) = %q, but re-parsing Join(...) failed: %v", in, args, err)
+		}
+		if !reflect.DeepEqual(args, out) {
+			t.Fatalf("ParsePOSIX(%q) = %q is not a fixed point under Join/ParsePOSIX: got %q", in, args, out)
+		}
+	})
+}
+
+func FuzzJoinParsePOSIX(f *testing.F) {
+	f.Add("abc", "def", "")
+	f.Add("a b", "it's", `#not-a-comment`)
+	f.Add(`"quoted"`, "line\ncontinuation\\", `\`)
+	f.Fuzz(func(t *testing.T, a, b, c string) {
+		in := []string{a, b, c}
+		line := Join(in)
+		out, err := ParsePOSIX(strings.NewReader(line))
+		if err != nil {
+			t.Fatalf("ParsePOSIX(Join(%q)) = %q, error: %v", in, line, err)
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Fatalf("round trip mismatch: in=%q line=%q out=%q", in, line, out)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// Quoting identifies which kind of token produced a Segment.
+type Quoting byte
+
+const (
+	Unquoted Quoting = iota
+	SingleQuoted
+	DoubleQuoted
+)
+
+/*
+Segment is one piece of an output argument as originally delimited by the
+scanner, before Parse concatenates adjacent pieces into a single string.
+Retaining Quoting alongside Text is what lets Expand decide, per piece,
+whether it's eligible for variable or command substitution - the same way a
+shell never expands anything inside single quotes.
+
+For a DoubleQuoted Segment, Raw is the piece's inner text exactly as written
+in the source, before the narrower \\ and \" escape substitution Parse
+documents for double-quoted tokens.  For Unquoted and SingleQuoted Segments
+Raw is identical to Text: a single-quoted piece has no escapes, and an
+unquoted piece's backslash escapes are already resolved and not retained.  Expand
+reads Raw instead of Text for a DoubleQuoted Segment, because recognizing
+which characters were backslash-escaped in the source, not merely what they
+decoded to, is required to suppress expansion of an escaped $, `, or \" the
+way a shell does.
+*/
+type Segment struct {
+	Text   string
+	Raw    string
+	Quoted Quoting
+}
+
+/*
+ParseSegments performs the same tokenization as Parse, but instead of
+collapsing each output argument down to a single string, it returns the
+ordered list of Segments that were concatenated to build it.  Parse is
+implemented in terms of ParseSegments, discarding the Quoting once the pieces
+are joined; Expand calls ParseSegments directly because it needs that
+quoting context to be preserved.
+*/
+func ParseSegments(
+	argLine io.Reader,
+) (
+	args [][]Segment,
+	err error,
+) {
+	if argLine == nil {
+		return nil, fmt.Errorf("nil io.Reader passed to parser")
+	}
+	escDbl, _ := regexp.Compile(`(\\\\)|(\\")`)
+	escAll, _ := regexp.Compile(`\\.`)
+
+	var wordCurr []Segment
+	var textCurr string
+	s := cliConfig(argLine)
+	for s.Scan() {
+		// Note the scanner relies on the parser semantics below to improve its
+		// performance. If these semantics change, the scanner may also
+		// require coding changes.
+		switch tokenIDextract(s.Bytes()) {
+		case tArgument:
+			piece := escapeSubstitute(tokenExtract(s.Bytes()), escAll)
+			textCurr += piece
+			wordCurr = append(wordCurr, Segment{Text: piece, Raw: piece, Quoted: Unquoted})
+		case tArgumentEncap:
+			raw := tokenExtract(s.Bytes())
+			quoting := SingleQuoted
+			if raw[0] == '"' {
+				quoting = DoubleQuoted
+			}
+			piece := tokenExpose(raw, escDbl)
+			inner := piece
+			if quoting == DoubleQuoted {
+				inner = string(raw[1 : len(raw)-1])
+			}
+			textCurr += piece
+			wordCurr = append(wordCurr, Segment{Text: piece, Raw: inner, Quoted: quoting})
+		case tWhiteSpace:
+			if textCurr != "" {
+				args = append(args, wordCurr)
+			}
+			wordCurr = nil
+			textCurr = ""
+		}
+	}
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	if textCurr != "" {
+		args = append(args, wordCurr)
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// TokenKind identifies the lexical category of a Token emitted by ParseStream.
+type TokenKind byte
+
+const (
+	TokenUnencap TokenKind = iota
+	TokenSingleQuoted
+	TokenDoubleQuoted
+	TokenWhitespace
+)
+
+/*
+Token is a single lexical unit recognized while scanning a stream.  Unlike the
+[]string returned by Parse, a Token retains enough positional information for
+a caller to render a caret-under-token error message or reconstruct the exact
+source span it came from.
+*/
+type Token struct {
+	Raw    []byte    // the unprocessed bytes as they appeared in the input - quotes included, escapes unresolved.
+	Text   string    // the decoded value - quotes stripped and escapes resolved per Kind, as Parse would produce.
+	Offset int       // byte offset of Raw[0] within the stream.
+	Line   int       // 1-based line number of Raw[0].
+	Column int       // 1-based column (byte count since the preceding newline, or start of input) of Raw[0].
+	Kind   TokenKind // the category of token: unencapsulated, single-quoted, double-quoted, or whitespace.
+}
+
+/*
+ParseStream scans argLine incrementally, emitting a Token on the returned
+channel as soon as it's recognized, rather than buffering the whole input and
+returning a []string the way Parse does.  This makes it suitable as the
+front-end of an interactive REPL: a caller can act on a newline-terminated
+statement as soon as its tokens arrive instead of waiting for the reader to
+reach EOF.
+
+Both returned channels are closed when scanning ends, whether because the
+reader was exhausted or because an error occurred; at most one error is ever
+sent on the error channel. A caller that wants every token must drain the
+token channel to completion - the scanning goroutine blocks sending a Token
+until the caller receives it.
+*/
+func ParseStream(
+	argLine io.Reader,
+) (
+	<-chan Token,
+	<-chan error,
+) {
+	tokens := make(chan Token)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(tokens)
+		defer close(errs)
+		if argLine == nil {
+			errs <- fmt.Errorf("nil io.Reader passed to parser")
+			return
+		}
+		escDbl, _ := regexp.Compile(`(\\\\)|(\\")`)
+		escAll, _ := regexp.Compile(`\\.`)
+		offset, line, col := 0, 1, 1
+		s := cliConfig(argLine)
+		for s.Scan() {
+			raw := tokenExtract(s.Bytes())
+			tok := Token{
+				Raw:    append([]byte(nil), raw...),
+				Offset: offset,
+				Line:   line,
+				Column: col,
+			}
+			switch tokenIDextract(s.Bytes()) {
+			case tArgument:
+				tok.Kind = TokenUnencap
+				tok.Text = escapeSubstitute(raw, escAll)
+			case tArgumentEncap:
+				if raw[0] == '"' {
+					tok.Kind = TokenDoubleQuoted
+				} else {
+					tok.Kind = TokenSingleQuoted
+				}
+				tok.Text = tokenExpose(raw, escDbl)
+			case tWhiteSpace:
+				tok.Kind = TokenWhitespace
+				tok.Text = string(raw)
+			}
+			tokens <- tok
+			offset += len(raw)
+			for _, b := range raw {
+				if b == '\n' {
+					line++
+					col = 1
+				} else {
+					col++
+				}
+			}
+		}
+		if s.Err() != nil {
+			errs <- s.Err()
+		}
+	}()
+	return tokens, errs
+}
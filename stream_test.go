@@ -0,0 +1,73 @@
+package argp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collectTokens(tokens <-chan Token, errs <-chan error) (toks []Token, err error) {
+	for tokens != nil || errs != nil {
+		select {
+		case tok, ok := <-tokens:
+			if !ok {
+				tokens = nil
+				continue
+			}
+			toks = append(toks, tok)
+		case e, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			err = e
+		}
+	}
+	return
+}
+
+func TestParseStreamNilReader(t *testing.T) {
+	assrt := assert.New(t)
+	tokens, errs := ParseStream(nil)
+	toks, err := collectTokens(tokens, errs)
+	assrt.NotNil(err)
+	assrt.Nil(toks)
+}
+func TestParseStreamTokensAndKinds(t *testing.T) {
+	assrt := assert.New(t)
+	tokens, errs := ParseStream(strings.NewReader(`abc "de f" 'gh'`))
+	toks, err := collectTokens(tokens, errs)
+	assrt.Nil(err)
+	assrt.Equal(5, len(toks))
+	assrt.Equal(TokenUnencap, toks[0].Kind)
+	assrt.Equal(`abc`, toks[0].Text)
+	assrt.Equal(0, toks[0].Offset)
+	assrt.Equal(TokenWhitespace, toks[1].Kind)
+	assrt.Equal(TokenDoubleQuoted, toks[2].Kind)
+	assrt.Equal(`de f`, toks[2].Text)
+	assrt.Equal(TokenSingleQuoted, toks[4].Kind)
+	assrt.Equal(`gh`, toks[4].Text)
+}
+func TestParseStreamLineAndColumn(t *testing.T) {
+	assrt := assert.New(t)
+	tokens, errs := ParseStream(strings.NewReader("abc\ndef"))
+	toks, err := collectTokens(tokens, errs)
+	assrt.Nil(err)
+	assrt.Equal(1, toks[0].Line)
+	assrt.Equal(1, toks[0].Column)
+	var last Token
+	for _, tok := range toks {
+		if tok.Text == "def" {
+			last = tok
+		}
+	}
+	assrt.Equal(2, last.Line)
+	assrt.Equal(1, last.Column)
+}
+func TestParseStreamUnterminatedQuoteError(t *testing.T) {
+	assrt := assert.New(t)
+	tokens, errs := ParseStream(strings.NewReader(`"abc`))
+	_, err := collectTokens(tokens, errs)
+	assrt.NotNil(err)
+}